@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// adminTokenEnv is the environment variable holding the bearer token
+// required to call the /admin/* management endpoints.
+const adminTokenEnv = "ATLAS_ADMIN_TOKEN"
+
+// adminToken is loaded once at startup. When empty, the admin API is
+// disabled entirely rather than left open.
+var adminToken string
+
+func loadAdminToken() {
+	adminToken = os.Getenv(adminTokenEnv)
+}
+
+// requireAdminAuth wraps next with a bearer-token check against adminToken.
+func requireAdminAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" {
+			http.Error(w, "admin API disabled: "+adminTokenEnv+" not set", http.StatusServiceUnavailable)
+			return
+		}
+
+		authz := r.Header.Get("Authorization")
+		const prefix = "Bearer "
+		if !strings.HasPrefix(authz, prefix) || strings.TrimPrefix(authz, prefix) != adminToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+type createChallengeRequest struct {
+	Token string `json:"token"`
+	TTL   string `json:"ttl"`
+}
+
+type challengeSummary struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// handleCreateChallenge implements POST /admin/challenges.
+func handleCreateChallenge(store *ChallengeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req createChallengeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+
+		ttl := defaultChallengeTTL
+		if req.TTL != "" {
+			parsed, err := time.ParseDuration(req.TTL)
+			if err != nil {
+				http.Error(w, "invalid ttl: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			ttl = parsed
+		}
+
+		if err := store.Add(req.Token, ttl); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"status": "created"})
+	}
+}
+
+// handleDeleteChallenge implements DELETE /admin/challenges/{token}.
+func handleDeleteChallenge(store *ChallengeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := strings.TrimPrefix(r.URL.Path, "/admin/challenges/")
+		if token == "" || strings.Contains(token, "/") {
+			http.Error(w, "invalid path", http.StatusBadRequest)
+			return
+		}
+
+		if !store.Remove(token) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleListChallenges implements GET /admin/challenges.
+func handleListChallenges(store *ChallengeStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		entries := store.List()
+		summaries := make([]challengeSummary, 0, len(entries))
+		for _, entry := range entries {
+			summaries = append(summaries, challengeSummary{
+				Token:     entry.token,
+				CreatedAt: entry.createdAt,
+				ExpiresAt: entry.expiresAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summaries)
+	}
+}
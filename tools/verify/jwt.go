@@ -0,0 +1,179 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// nodeKeyEnv is the fallback environment variable holding the node's signing
+// key material directly (as opposed to --node-key, which names a file).
+const nodeKeyEnv = "ATLAS_NODE_KEY"
+
+// jwtTTL bounds how long a signed verification response is valid for.
+const jwtTTL = 5 * time.Minute
+
+type signerKind int
+
+const (
+	signerNone signerKind = iota
+	signerEd25519
+	signerHMAC
+)
+
+// nodeSigner signs challenge responses so a verifier can confirm the
+// responding node controls the registered key, not just that it echoed the
+// challenge text.
+type nodeSigner struct {
+	kind    signerKind
+	priv    ed25519.PrivateKey
+	pub     ed25519.PublicKey
+	hmacKey []byte
+	keyID   string
+}
+
+func (s *nodeSigner) algName() string {
+	switch s.kind {
+	case signerEd25519:
+		return "EdDSA"
+	case signerHMAC:
+		return "HS256"
+	default:
+		return ""
+	}
+}
+
+// verifyClaims is the JWT payload returned in place of the plain
+// "node-verify:<challenge>" body when a node key is configured.
+type verifyClaims struct {
+	Challenge string `json:"challenge"`
+	NodeID    string `json:"node_id"`
+	Nonce     string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+func (s *nodeSigner) sign(challenge string) (string, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	now := time.Now()
+	claims := verifyClaims{
+		Challenge: challenge,
+		NodeID:    nodeID,
+		Nonce:     nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(jwtTTL)),
+		},
+	}
+
+	var method jwt.SigningMethod
+	var key interface{}
+	switch s.kind {
+	case signerEd25519:
+		method = jwt.SigningMethodEdDSA
+		key = s.priv
+	case signerHMAC:
+		method = jwt.SigningMethodHS256
+		key = s.hmacKey
+	default:
+		return "", fmt.Errorf("no signing key configured")
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+	return token.SignedString(key)
+}
+
+func randomNonce() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// loadNodeSigner reads key material from --node-key (a file path) or, if
+// that flag is unset, the ATLAS_NODE_KEY environment variable (the key
+// material itself). A PEM-encoded PKCS#8 Ed25519 private key enables
+// EdDSA signing; any other non-empty value is treated as a raw HMAC
+// secret. Returns (nil, nil) when no key material is configured at all,
+// leaving signed responses disabled.
+func loadNodeSigner(keyPath, keyID string) (*nodeSigner, error) {
+	var raw []byte
+	switch {
+	case keyPath != "":
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --node-key: %w", err)
+		}
+		raw = data
+	case os.Getenv(nodeKeyEnv) != "":
+		raw = []byte(os.Getenv(nodeKeyEnv))
+	default:
+		return nil, nil
+	}
+
+	raw = []byte(strings.TrimSpace(string(raw)))
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if block, _ := pem.Decode(raw); block != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM private key: %w", err)
+		}
+		priv, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("PEM private key is not Ed25519")
+		}
+		return &nodeSigner{
+			kind:  signerEd25519,
+			priv:  priv,
+			pub:   priv.Public().(ed25519.PublicKey),
+			keyID: keyID,
+		}, nil
+	}
+
+	return &nodeSigner{kind: signerHMAC, hmacKey: raw, keyID: keyID}, nil
+}
+
+// handlePubkey implements GET /.well-known/node-verify/pubkey. For HMAC
+// signers only the algorithm and key id are published, since the secret
+// itself must stay private.
+func handlePubkey(w http.ResponseWriter, r *http.Request) {
+	if signer == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	resp := map[string]string{
+		"node_id": nodeID,
+		"alg":     signer.algName(),
+	}
+	if signer.keyID != "" {
+		resp["kid"] = signer.keyID
+	}
+	if signer.kind == signerEd25519 {
+		resp["public_key"] = base64.StdEncoding.EncodeToString(signer.pub)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
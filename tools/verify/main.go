@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,51 +14,140 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/netutil"
 )
 
 const (
 	Version = "1.0.0"
 	Port    = "8080"
+
+	janitorInterval = time.Minute
+	shutdownTimeout = 10 * time.Second
 )
 
 var (
-	wellKnownDir  = ".well-known/node-verify"
-	challengeFile string
-	challenge     string
+	wellKnownDir = ".well-known/node-verify"
+	store        = NewChallengeStore()
+	janitorStop  = make(chan struct{})
+
+	tlsCert  string
+	tlsKey   string
+	bindAddr string
+	maxConns int
+
+	nodeKeyPath string
+	nodeID      string
+	nodeKeyID   string
+	signer      *nodeSigner
+
+	waitForVerification string
+	pollInterval        time.Duration
+	retryTimeout        time.Duration
 )
 
+func init() {
+	flag.StringVar(&tlsCert, "tls-cert", "", "path to TLS certificate file (enables HTTPS)")
+	flag.StringVar(&tlsKey, "tls-key", "", "path to TLS private key file (enables HTTPS)")
+	flag.StringVar(&bindAddr, "bind", ":"+Port, "address to listen on")
+	flag.IntVar(&maxConns, "max-conns", 0, "maximum concurrent connections (0 = unlimited)")
+	flag.StringVar(&nodeKeyPath, "node-key", "", "path to a signing key file (Ed25519 PEM or raw HMAC secret); falls back to "+nodeKeyEnv)
+	flag.StringVar(&nodeID, "node-id", "", "node identifier embedded in signed responses")
+	flag.StringVar(&nodeKeyID, "node-key-id", "", "key id (kid) advertised alongside the public key")
+	flag.StringVar(&waitForVerification, "wait-for-verification", "", "controller base URL; if set, poll it until verification succeeds then exit (non-interactive mode)")
+	flag.DurationVar(&pollInterval, "poll-interval", 5*time.Second, "interval between verification status polls")
+	flag.DurationVar(&retryTimeout, "retry-timeout", 5*time.Minute, "give up and exit 3 if verification isn't confirmed within this long")
+}
+
 func main() {
+	flag.Usage = printUsage
+	flag.Parse()
+
 	printBanner()
 
-	if len(os.Args) < 2 {
+	if flag.NArg() < 1 {
 		printUsage()
 		os.Exit(1)
 	}
 
-	challenge = os.Args[1]
+	challenge := flag.Arg(0)
 
 	// Validate challenge format (alphanumeric, length 20-128)
 	if !isValidChallenge(challenge) {
 		log.Fatal("❌ Invalid challenge format. Must be alphanumeric, 20-128 characters.")
 	}
 
-	// Create verification file
-	if err := createVerificationFile(); err != nil {
+	if err := store.Add(challenge, 0); err != nil {
 		log.Fatalf("❌ Failed to create verification file: %v", err)
 	}
+	fmt.Printf("✅ Created verification file: %s\n", challengeFilePath(challenge))
 
-	// Setup cleanup on exit
-	setupCleanup()
+	if nodeID == "" {
+		if hostname, err := os.Hostname(); err == nil {
+			nodeID = hostname
+		}
+	}
+	var err error
+	signer, err = loadNodeSigner(nodeKeyPath, nodeKeyID)
+	if err != nil {
+		log.Fatalf("❌ Failed to load node signing key: %v", err)
+	}
+	if signer != nil {
+		fmt.Printf("🔑 Signing verification responses with %s (node_id=%s)\n", signer.algName(), nodeID)
+	}
+
+	loadAdminToken()
+	registerActiveChallengesGauge(store)
+	go store.runJanitor(janitorInterval, janitorStop)
 
 	// Start HTTP server
 	srv := createServer()
 
-	fmt.Printf("\n✅ Verification server running on port %s\n", Port)
-	fmt.Printf("📡 Verification URL: http://YOUR_NODE_IP:%s/.well-known/node-verify/%s\n", Port, challenge)
+	ln, err := net.Listen("tcp", bindAddr)
+	if err != nil {
+		log.Fatalf("❌ Failed to bind %s: %v", bindAddr, err)
+	}
+	if maxConns > 0 {
+		ln = netutil.LimitListener(ln, maxConns)
+	}
+
+	// Setup cleanup on exit
+	setupCleanup(srv)
+
+	scheme := "http"
+	if tlsCert != "" || tlsKey != "" {
+		scheme = "https"
+	}
+	fmt.Printf("\n✅ Verification server running on %s (%s)\n", bindAddr, scheme)
+	fmt.Printf("📡 Verification URL: %s://YOUR_NODE_IP%s/.well-known/node-verify/%s\n", scheme, bindAddr, challenge)
 	fmt.Printf("\n⏳ Waiting for verification request...\n")
 	fmt.Printf("💡 Press Ctrl+C to stop the server\n\n")
 
-	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+	if scheme == "https" && (tlsCert == "" || tlsKey == "") {
+		log.Fatal("❌ both --tls-cert and --tls-key must be set to enable TLS")
+	}
+
+	serveErrs := make(chan error, 1)
+	go func() {
+		if scheme == "https" {
+			serveErrs <- srv.ServeTLS(ln, tlsCert, tlsKey)
+		} else {
+			serveErrs <- srv.Serve(ln)
+		}
+	}()
+
+	// In --wait-for-verification mode the server keeps running in the
+	// background just to answer the probe, while this goroutine polls the
+	// controller and decides when the process should exit.
+	if waitForVerification != "" {
+		code := pollUntilVerified(waitForVerification, challenge, pollInterval, retryTimeout)
+		shutdownServer(srv)
+		cleanup()
+		os.Exit(code)
+	}
+
+	if err := <-serveErrs; err != nil && err != http.ErrServerClosed {
 		log.Fatalf("❌ Server error: %v", err)
 	}
 }
@@ -70,12 +162,17 @@ func printBanner() {
 
 func printUsage() {
 	fmt.Println("Usage:")
-	fmt.Printf("  %s <challenge-token>\n\n", os.Args[0])
+	fmt.Printf("  %s [flags] <challenge-token>\n\n", os.Args[0])
 	fmt.Println("Example:")
 	fmt.Printf("  %s abc123xyz456def789\n\n", os.Args[0])
 	fmt.Println("Description:")
 	fmt.Println("  Starts an HTTP server on port 8080 to prove node ownership.")
 	fmt.Println("  The server will respond to verification requests with your challenge token.")
+	fmt.Println("  Additional challenges can be registered at runtime via the /admin/challenges")
+	fmt.Println("  API (see " + adminTokenEnv + ") so one server can stand in for many nodes.")
+	fmt.Println()
+	fmt.Println("Flags:")
+	flag.PrintDefaults()
 	fmt.Println()
 }
 
@@ -88,46 +185,39 @@ func isValidChallenge(s string) bool {
 	return match
 }
 
-func createVerificationFile() error {
-	// Create .well-known directory
-	if err := os.MkdirAll(wellKnownDir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Create challenge file
-	challengeFile = filepath.Join(wellKnownDir, challenge)
-	content := "node-verify:" + challenge
-
-	if err := os.WriteFile(challengeFile, []byte(content), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
-	}
-
-	fmt.Printf("✅ Created verification file: %s\n", challengeFile)
-	return nil
-}
-
-func setupCleanup() {
+func setupCleanup(srv *http.Server) {
 	// Handle Ctrl+C and termination signals
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		<-c
+		fmt.Println("\n🛑 Shutting down gracefully...")
+		shutdownServer(srv)
 		cleanup()
 		os.Exit(0)
 	}()
 }
 
+// shutdownServer lets in-flight requests finish before the process exits,
+// whether that exit was triggered by a signal or by --wait-for-verification
+// concluding.
+func shutdownServer(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  Graceful shutdown error: %v", err)
+	}
+}
+
 func cleanup() {
 	fmt.Println("\n🧹 Cleaning up...")
 
-	// Remove challenge file
-	if challengeFile != "" {
-		if err := os.Remove(challengeFile); err != nil {
-			log.Printf("⚠️  Failed to remove challenge file: %v", err)
-		} else {
-			fmt.Printf("✅ Removed %s\n", challengeFile)
-		}
+	close(janitorStop)
+
+	for _, entry := range store.List() {
+		store.Remove(entry.token)
+		fmt.Printf("✅ Removed %s\n", challengeFilePath(entry.token))
 	}
 
 	// Remove .well-known directory if empty
@@ -170,25 +260,53 @@ func createServer() *http.Server {
 			return
 		}
 
-		// Verify token matches challenge
-		if token != challenge {
+		// Verify token is a currently registered challenge
+		if _, ok := store.Get(token); !ok {
 			http.NotFound(w, r)
 			return
 		}
 
-		// Log the request
-		timestamp := time.Now().Format("2006-01-02 15:04:05")
-		fmt.Printf("[%s] 📡 Verification request from %s\n", timestamp, r.RemoteAddr)
+		// Serve the verification content. When a node signing key is
+		// configured, respond with a signed JWT instead of plain text so
+		// the verifier can confirm the node controls the registered key.
+		if signer != nil {
+			signed, err := signer.sign(token)
+			if err != nil {
+				http.Error(w, "failed to sign response", http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/jwt")
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(signed))
+		} else {
+			content := "node-verify:" + token
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(content))
+		}
+	})
 
-		// Serve the verification content
-		content := "node-verify:" + challenge
-		w.Header().Set("Content-Type", "text/plain")
-		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(content))
+	// Publishes the node's public key (or key metadata) so verifiers can
+	// check the signature on signed challenge responses.
+	mux.HandleFunc("/.well-known/node-verify/pubkey", handlePubkey)
+
+	// Admin API for managing active challenges (bearer-token protected)
+	mux.HandleFunc("/admin/challenges", requireAdminAuth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handleCreateChallenge(store)(w, r)
+		case http.MethodGet:
+			handleListChallenges(store)(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+	mux.HandleFunc("/admin/challenges/", requireAdminAuth(handleDeleteChallenge(store)))
 
-		fmt.Printf("[%s] ✅ Verification response sent\n", timestamp)
-	})
+	// Prometheus metrics
+	mux.Handle("/metrics", promhttp.Handler())
 
 	// Health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -207,13 +325,13 @@ func createServer() *http.Server {
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusOK)
 		fmt.Fprintf(w, "AtlasP2P Node Verification Server v%s\n\n", Version)
-		fmt.Fprintf(w, "Verification endpoint: /.well-known/node-verify/%s\n", challenge[:10]+"...")
+		fmt.Fprintf(w, "Active challenges: %d\n", len(store.List()))
 		fmt.Fprintf(w, "Status: Running\n")
 	})
 
 	return &http.Server{
-		Addr:         ":" + Port,
-		Handler:      mux,
+		Addr:         bindAddr,
+		Handler:      accessLogMiddleware(mux),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  30 * time.Second,
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	verificationRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "atlasp2p_verification_requests_total",
+		Help: "Total number of verification requests handled, labeled by result.",
+	}, []string{"result"})
+
+	verificationRequestDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "atlasp2p_verification_request_duration_seconds",
+		Help:    "Latency of verification requests.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// registerActiveChallengesGauge exposes atlasp2p_active_challenges, sampled
+// from store on every scrape.
+func registerActiveChallengesGauge(store *ChallengeStore) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "atlasp2p_active_challenges",
+		Help: "Number of currently active (non-expired) challenge tokens.",
+	}, func() float64 {
+		return float64(len(store.List()))
+	})
+}
+
+// accessLogEntry is emitted as one JSON line per request in place of the
+// old ad-hoc fmt.Printf logging.
+type accessLogEntry struct {
+	Timestamp        string  `json:"ts"`
+	Remote           string  `json:"remote"`
+	Method           string  `json:"method"`
+	Path             string  `json:"path"`
+	Status           int     `json:"status"`
+	Bytes            int     `json:"bytes"`
+	DurationMS       float64 `json:"duration_ms"`
+	ChallengeMatched bool    `json:"challenge_matched"`
+}
+
+type statusCapturingWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusCapturingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusCapturingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// accessLogMiddleware logs one structured JSON line per request and records
+// Prometheus counters/histograms for the node-verify endpoint.
+func accessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusCapturingWriter{ResponseWriter: w}
+
+		next.ServeHTTP(sw, r)
+
+		duration := time.Since(start)
+		isVerifyPath := strings.HasPrefix(r.URL.Path, "/.well-known/node-verify/") && r.URL.Path != "/.well-known/node-verify/pubkey"
+		matched := isVerifyPath && sw.status == http.StatusOK
+
+		entry := accessLogEntry{
+			Timestamp:        start.UTC().Format(time.RFC3339),
+			Remote:           r.RemoteAddr,
+			Method:           r.Method,
+			Path:             r.URL.Path,
+			Status:           sw.status,
+			Bytes:            sw.bytes,
+			DurationMS:       float64(duration.Microseconds()) / 1000,
+			ChallengeMatched: matched,
+		}
+		if data, err := json.Marshal(entry); err == nil {
+			fmt.Println(string(data))
+		}
+
+		if isVerifyPath {
+			result := "not_found"
+			if matched {
+				result = "matched"
+			}
+			verificationRequestsTotal.WithLabelValues(result).Inc()
+			verificationRequestDuration.Observe(duration.Seconds())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// verifyStatusResponse is the expected shape of the controller's
+// verify-status endpoint.
+type verifyStatusResponse struct {
+	Status string `json:"status"` // "pending", "verified", or "failed"
+}
+
+// pollUntilVerified polls controller's verify-status endpoint for challenge
+// every interval until it reports success (returns 0), timeout elapses
+// (returns 3), or a terminal "failed" status is reported (returns 1). This
+// lets the tool run unattended in provisioning scripts instead of requiring
+// an operator to watch the logs and Ctrl+C once satisfied.
+func pollUntilVerified(controller, challenge string, interval, timeout time.Duration) int {
+	client := &http.Client{Timeout: 10 * time.Second}
+	start := time.Now()
+
+	for {
+		elapsed := time.Since(start)
+		if elapsed > timeout {
+			log.Printf("⏱️  retry-timeout (%s) exceeded after %s, giving up", timeout, elapsed.Round(time.Second))
+			return 3
+		}
+
+		status, err := fetchVerificationStatus(client, controller, challenge)
+		if err != nil {
+			log.Printf("⚠️  verification status check failed: %v", err)
+		} else {
+			switch status.Status {
+			case "verified":
+				fmt.Println("✅ Controller confirmed verification")
+				return 0
+			case "failed":
+				log.Printf("❌ Controller reported verification failed")
+				return 1
+			default:
+				log.Printf("⏳ not verified yet (status=%q, elapsed %s/%s)", status.Status, elapsed.Round(time.Second), timeout)
+			}
+		}
+
+		if elapsed+interval > timeout {
+			log.Printf("⏱️  next poll would exceed retry-timeout (%s), giving up", timeout)
+			return 3
+		}
+		time.Sleep(interval)
+	}
+}
+
+func fetchVerificationStatus(client *http.Client, controller, challenge string) (*verifyStatusResponse, error) {
+	u, err := url.Parse(strings.TrimSuffix(controller, "/") + "/api/nodes/verify-status")
+	if err != nil {
+		return nil, fmt.Errorf("invalid controller URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("challenge", challenge)
+	u.RawQuery = q.Encode()
+
+	resp, err := client.Get(u.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("controller returned status %d", resp.StatusCode)
+	}
+
+	var status verifyStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &status, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultChallengeTTL is used when an admin-registered challenge omits a TTL.
+const defaultChallengeTTL = 24 * time.Hour
+
+// challengeEntry tracks a single active challenge token and when it expires.
+type challengeEntry struct {
+	token     string
+	createdAt time.Time
+	expiresAt time.Time
+}
+
+func (e *challengeEntry) expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// ChallengeStore keeps track of every challenge token the server is
+// currently willing to answer for. This lets one process stand in for many
+// nodes (or many challenge rotations of the same node) instead of the
+// single global challenge the tool started with.
+type ChallengeStore struct {
+	mu      sync.RWMutex
+	entries map[string]*challengeEntry
+}
+
+// NewChallengeStore returns an empty store.
+func NewChallengeStore() *ChallengeStore {
+	return &ChallengeStore{entries: make(map[string]*challengeEntry)}
+}
+
+// Add registers token with the given ttl, writing its verification file to
+// disk so it can also be served by a plain static file server. A ttl <= 0
+// falls back to defaultChallengeTTL.
+func (s *ChallengeStore) Add(token string, ttl time.Duration) error {
+	if !isValidChallenge(token) {
+		return fmt.Errorf("invalid challenge format: %q", token)
+	}
+	if ttl <= 0 {
+		ttl = defaultChallengeTTL
+	}
+
+	now := time.Now()
+	entry := &challengeEntry{
+		token:     token,
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}
+
+	if err := writeChallengeFile(token); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.entries[token] = entry
+	s.mu.Unlock()
+	return nil
+}
+
+// Remove deregisters token and removes its verification file, if any.
+func (s *ChallengeStore) Remove(token string) bool {
+	s.mu.Lock()
+	_, ok := s.entries[token]
+	delete(s.entries, token)
+	s.mu.Unlock()
+
+	if ok {
+		removeChallengeFile(token)
+	}
+	return ok
+}
+
+// Get returns the entry for token, treating expired entries as absent.
+func (s *ChallengeStore) Get(token string) (*challengeEntry, bool) {
+	s.mu.RLock()
+	entry, ok := s.entries[token]
+	s.mu.RUnlock()
+
+	if !ok || entry.expired(time.Now()) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// List returns every non-expired entry, in no particular order.
+func (s *ChallengeStore) List() []*challengeEntry {
+	now := time.Now()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := make([]*challengeEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if !entry.expired(now) {
+			active = append(active, entry)
+		}
+	}
+	return active
+}
+
+// sweepExpired removes entries (and their verification files) whose TTL has
+// elapsed. It is intended to be called periodically from a background
+// goroutine started in main.
+func (s *ChallengeStore) sweepExpired() {
+	now := time.Now()
+
+	var expired []string
+	s.mu.Lock()
+	for token, entry := range s.entries {
+		if entry.expired(now) {
+			expired = append(expired, token)
+			delete(s.entries, token)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, token := range expired {
+		removeChallengeFile(token)
+	}
+}
+
+// runJanitor sweeps expired challenges every interval until stop is closed.
+func (s *ChallengeStore) runJanitor(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweepExpired()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func challengeFilePath(token string) string {
+	return filepath.Join(wellKnownDir, token)
+}
+
+func writeChallengeFile(token string) error {
+	if err := os.MkdirAll(wellKnownDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	content := "node-verify:" + token
+	if err := os.WriteFile(challengeFilePath(token), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func removeChallengeFile(token string) {
+	os.Remove(challengeFilePath(token))
+}